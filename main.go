@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/mehdi-hexing/Cloudflare-Clean-IP-Scanner/task"
+	"github.com/mehdi-hexing/Cloudflare-Clean-IP-Scanner/utils"
+)
+
+func init() {
+	var printVersion bool
+
+	flag.IntVar(&task.Routines, "n", task.Routines, "Latency test routines")
+	flag.IntVar(&task.PingTimes, "t", task.PingTimes, "Times to test each IP ")
+	flag.IntVar(&task.TCPPort, "tp", task.TCPPort, "Latency test port")
+	flag.DurationVar(&utils.InputMaxDelay, "tl", utils.InputMaxDelay, "Upper limit of average delay")
+	flag.DurationVar(&utils.InputMinDelay, "tll", utils.InputMinDelay, "Lower limit of average delay")
+	flag.Float64Var(&task.MinSpeed, "sl", task.MinSpeed, "Lower limit of download speed (MB/s)")
+	flag.IntVar(&task.TestCount, "dn", task.TestCount, "Number of IPs to run the download test on")
+	flag.DurationVar(&task.Timeout, "dt", task.Timeout, "Download test timeout for a single IP")
+	flag.BoolVar(&task.Disabled, "dd", task.Disabled, "Disable the download speed test")
+	flag.StringVar(&task.URL, "url", task.URL, "URL used for the download speed test")
+	flag.StringVar(&task.IPFile, "f", task.IPFile, "IP range file")
+	flag.StringVar(&task.IPText, "ip", task.IPText, "Comma separated IPs/CIDRs, overrides -f")
+	flag.BoolVar(&task.TestAll, "test-all", task.TestAll, "Test every address in each IPv4 /24 instead of sampling one (IPv6 ranges are always sampled)")
+	flag.StringVar(&task.Countries, "cc", task.Countries, "Comma separated country codes to keep, e.g. US,DE,JP")
+	flag.StringVar(&task.CCFile, "cc-file", task.CCFile, "IP-to-country table used by -cc (CSV: start,end,CC)")
+	flag.StringVar(&utils.Output, "o", utils.Output, "Output file name")
+	flag.IntVar(&utils.PrintNum, "p", utils.PrintNum, "Number of results to print, 0 disables printing")
+	flag.BoolVar(&printVersion, "v", false, "Print the current version and exit")
+	flag.Parse()
+
+	if printVersion {
+		fmt.Println(task.Version)
+		os.Exit(0)
+	}
+}
+
+func main() {
+	updateCh := task.CheckUpdate()
+
+	ping := task.NewPing()
+	pingData := ping.Run().FilterDelay().FilterLossRate()
+	speedData := []utils.CloudflareIPData(task.TestDownloadSpeed(pingData))
+	utils.ExportCsv(speedData)
+	utils.PrintResult(speedData)
+
+	if newVersion := <-updateCh; newVersion != "" {
+		task.PrintUpdateNotice(newVersion)
+	}
+
+	if runtime.GOOS == "windows" {
+		fmt.Println("Press Enter to exit...")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,130 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/mehdi-hexing/Cloudflare-Clean-IP-Scanner/utils"
+)
+
+const (
+	bufferSize      = 1024
+	defaultURL      = "https://cf.xiu2.xyz/url"
+	defaultTimeout  = 10 * time.Second
+	defaultDisabled = false
+)
+
+var (
+	URL       = defaultURL
+	Timeout   = defaultTimeout
+	Disabled  = defaultDisabled
+	TestCount = 10
+	MinSpeed  = float64(0)
+)
+
+// download reads the response body for up to timeout, reporting an EWMA-smoothed
+// throughput in bytes/sec so TCP slow-start and short-lived jitter don't skew the result.
+func download(ip utils.CloudflareIPData, timeout time.Duration) float64 {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			_, port, _ := net.SplitHostPort(addr)
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+		},
+	}
+	client := &http.Client{
+		Timeout:   timeout + 2*time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return nil // follow redirects
+		},
+	}
+	req, err := http.NewRequest(http.MethodGet, URL, nil)
+	if err != nil {
+		return 0
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) CloudflareScanner/1.0")
+	req.Header.Set("Range", "bytes=0-")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return 0
+	}
+
+	const alpha = 0.3
+	var ewma float64
+	var intervalBytes int64
+	buf := make([]byte, bufferSize)
+	start := time.Now()
+	lastTick := start
+	now := start
+	timeEnd := start.Add(timeout)
+	updateEwma := func(elapsed time.Duration) {
+		if elapsed <= 0 || intervalBytes == 0 {
+			return
+		}
+		instantaneous := float64(intervalBytes) / elapsed.Seconds()
+		if ewma == 0 {
+			ewma = instantaneous
+		} else {
+			ewma = alpha*instantaneous + (1-alpha)*ewma
+		}
+		intervalBytes = 0
+		lastTick = now
+	}
+	for {
+		now = time.Now()
+		if now.After(timeEnd) {
+			break
+		}
+		n, err := resp.Body.Read(buf)
+		intervalBytes += int64(n)
+		if elapsed := now.Sub(lastTick); elapsed >= 100*time.Millisecond {
+			updateEwma(elapsed)
+		}
+		if err != nil {
+			break
+		}
+	}
+	// Fold whatever was read in the last, still-short interval so a fast/small
+	// response that never reaches a full 100ms tick isn't reported as 0 MB/s.
+	updateEwma(now.Sub(lastTick))
+	return ewma
+}
+
+// TestDownloadSpeed measures download throughput for the fastest-latency survivors,
+// stopping early once TestCount IPs have passed the MinSpeed filter.
+func TestDownloadSpeed(ips utils.PingDelaySet) (speedSet utils.DownloadSpeedSet) {
+	if Disabled {
+		return utils.DownloadSpeedSet(ips)
+	}
+	if len(ips) <= 0 {
+		fmt.Println("\n[Info] Latency test result is empty, skipping download speed test.")
+		return speedSet
+	}
+
+	fmt.Printf("Starting download speed test (only testing IPs that pass the latency test, timeout: %v)\n", Timeout)
+	passed := 0
+	for i := 0; i < len(ips); i++ {
+		speed := download(ips[i], Timeout)
+		ips[i].DownloadSpeed = speed
+
+		if speed >= MinSpeed*1024*1024 {
+			speedSet = append(speedSet, ips[i])
+			passed++
+		}
+		if passed >= TestCount {
+			break
+		}
+	}
+	sort.Sort(speedSet)
+	return
+}
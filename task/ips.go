@@ -0,0 +1,313 @@
+package task
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultInputFile = "ip.txt"
+)
+
+const defaultCCFile = "cc.txt"
+
+var (
+	IPFile    = defaultInputFile
+	IPText    string
+	TestAll   = false
+	Countries string // e.g. "US,DE,JP"
+	CCFile    = defaultCCFile
+)
+
+// IPRanges holds the host list to be scanned, built up by chooseIPv4/chooseIPv6,
+// along with the state of the CIDR range (or single IP) currently being expanded.
+type IPRanges struct {
+	ips     []*net.IPAddr
+	mask    string
+	firstIP net.IP
+	ipNet   *net.IPNet
+}
+
+func newIPRanges() *IPRanges {
+	return &IPRanges{}
+}
+
+// Count returns how many addresses have been loaded so far, used to size the progress bar.
+func (r *IPRanges) Count() int {
+	return len(r.ips)
+}
+
+func (r *IPRanges) setIPNet(n string) {
+	_, ipNet, err := net.ParseCIDR(n)
+	if err != nil {
+		return
+	}
+	r.ipNet = ipNet
+	r.firstIP = ipNet.IP
+}
+
+// appendIPv4 adds one random host in the current /24 (or every host when TestAll is set).
+func (r *IPRanges) appendIPv4(ip string) {
+	if TestAll {
+		for i := 0; i <= 255; i++ {
+			r.appendIP(fmt.Sprintf("%s.%d", ip, i))
+		}
+	} else {
+		r.appendIP(fmt.Sprintf("%s.%d", ip, randIPEndWith(255)))
+	}
+}
+
+func (r *IPRanges) appendIP(ip string) {
+	r.ips = append(r.ips, &net.IPAddr{IP: net.ParseIP(ip)})
+}
+
+// chooseIPv4 expands the current /24-granularity CIDR network one octet at a time.
+func (r *IPRanges) chooseIPv4() {
+	if r.mask == "/32" { // single IP, no expansion needed
+		r.appendIP(r.firstIP.String())
+		return
+	}
+	var minIP, hostIP, maxIP net.IP
+	minIP = r.firstIP.Mask(r.ipNet.Mask)
+	maxIP = make(net.IP, len(minIP))
+	for i := range minIP {
+		maxIP[i] = minIP[i] | ^r.ipNet.Mask[i]
+	}
+	minIPNum := uint64(ipToUint32(minIP))
+	maxIPNum := uint64(ipToUint32(maxIP))
+	for ipNum := minIPNum; ipNum <= maxIPNum; ipNum += 256 {
+		hostIP = uint32ToIP(uint32(ipNum)).To4()
+		r.appendIPv4(fmt.Sprintf("%d.%d.%d", hostIP[0], hostIP[1], hostIP[2]))
+	}
+}
+
+// chooseIPv6 walks every /48 block between the network's first and last address
+// (a no-op single block when the supplied prefix is already /48 or narrower),
+// and samples one random host inside each block.
+func (r *IPRanges) chooseIPv6() {
+	if r.mask == "/128" {
+		r.appendIP(r.firstIP.String())
+		return
+	}
+	ones, _ := r.ipNet.Mask.Size()
+	minIP := r.firstIP.Mask(r.ipNet.Mask).To16()
+
+	maxIP := make(net.IP, 16)
+	copy(maxIP, minIP)
+	for i := range maxIP {
+		maxIP[i] |= ^r.ipNet.Mask[i]
+	}
+
+	minBlock := ip6BlockNum(minIP)
+	maxBlock := ip6BlockNum(maxIP)
+	hostPrefixLen := ones
+	if hostPrefixLen < 48 {
+		hostPrefixLen = 48
+	}
+
+	for block := minBlock; ; block++ {
+		ip := make(net.IP, 16)
+		copy(ip, minIP)
+		setIP6Block(ip, block)
+		r.appendIPv6Host(ip, hostPrefixLen)
+		if block == maxBlock { // avoid wrapping past the range's last /48
+			break
+		}
+	}
+}
+
+// ip6BlockNum returns the top 48 bits of ip (its /48 block number) as an integer.
+func ip6BlockNum(ip net.IP) uint64 {
+	var b [8]byte
+	copy(b[2:], ip[:6])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// setIP6Block writes block's low 48 bits into ip's top 6 bytes.
+func setIP6Block(ip net.IP, block uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], block)
+	copy(ip[:6], b[2:])
+}
+
+// appendIPv6Host randomizes every bit of block beyond prefixLen and records the result.
+func (r *IPRanges) appendIPv6Host(block net.IP, prefixLen int) {
+	ip := make(net.IP, 16)
+	copy(ip, block)
+	startByte := prefixLen / 8
+	if startBit := prefixLen % 8; startBit != 0 {
+		mask := byte(0xFF << (8 - startBit))
+		ip[startByte] = (ip[startByte] & mask) | (byte(rand.Intn(256)) &^ mask)
+		startByte++
+	}
+	for i := startByte; i < 16; i++ {
+		ip[i] = byte(rand.Intn(256))
+	}
+	r.ips = append(r.ips, &net.IPAddr{IP: ip})
+}
+
+func ipToUint32(ip net.IP) (sum uint32) {
+	ip = ip.To4()
+	for _, b := range ip {
+		sum = sum<<8 + uint32(b)
+	}
+	return
+}
+
+func uint32ToIP(sum uint32) net.IP {
+	return net.IPv4(byte(sum>>24), byte(sum>>16), byte(sum>>8), byte(sum))
+}
+
+// randIPEndWith returns a random byte in [0, num), matching upstream's last-octet randomization.
+func randIPEndWith(num byte) uint8 {
+	if num == 0 {
+		return 0
+	}
+	return uint8(rand.Intn(int(num)))
+}
+
+func isIPv4(ip string) bool {
+	return !strings.Contains(ip, ":")
+}
+
+// loadIPRanges builds the candidate IP set from -ip, -f, and -cc, honoring -test-all.
+func loadIPRanges() (ranges *IPRanges) {
+	ranges = newIPRanges()
+
+	if IPText != "" {
+		// -ip 1.1.1.1,2.2.2.0/24
+		IPs := strings.Split(IPText, ",")
+		for _, ip := range IPs {
+			ip = strings.TrimSpace(ip)
+			if ip == "" {
+				continue
+			}
+			loadIPRange(ranges, ip)
+		}
+	} else {
+		if IPFile == "" {
+			return
+		}
+		file, err := os.Open(IPFile)
+		if err != nil {
+			fmt.Printf("Failed to read IP file [%s]: %v\n", IPFile, err)
+			return
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			loadIPRange(ranges, line)
+		}
+	}
+
+	if Countries != "" {
+		if err := loadCCTable(CCFile); err != nil {
+			log.Fatalf("-cc was set but the country table [%s] could not be loaded: %v", CCFile, err)
+		}
+		ranges.filterByCountry(strings.Split(Countries, ","))
+	}
+
+	return
+}
+
+func loadIPRange(ranges *IPRanges, ip string) {
+	if !strings.Contains(ip, "/") {
+		if isIPv4(ip) {
+			ip += "/32"
+		} else {
+			ip += "/128"
+		}
+	}
+	ranges.mask = ip[strings.LastIndex(ip, "/"):]
+	ranges.setIPNet(ip)
+	if ranges.ipNet == nil {
+		return
+	}
+	if isIPv4(ip) {
+		ranges.chooseIPv4()
+	} else {
+		ranges.chooseIPv6()
+	}
+}
+
+// ccRange is one row of the sorted IPv4-integer-keyed country lookup table.
+type ccRange struct {
+	Start uint32
+	End   uint32
+	CC    string
+}
+
+var ccTable []ccRange
+
+// loadCCTable reads the IP-to-country table used by -cc, sorted by Start for binary search.
+func loadCCTable(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var table []ccRange
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(fields) != 3 {
+			continue
+		}
+		start := net.ParseIP(fields[0])
+		end := net.ParseIP(fields[1])
+		if start == nil || end == nil {
+			continue
+		}
+		table = append(table, ccRange{Start: ipToUint32(start), End: ipToUint32(end), CC: strings.ToUpper(fields[2])})
+	}
+	if len(table) == 0 {
+		return errors.New("no valid rows")
+	}
+	sort.Slice(table, func(i, j int) bool { return table[i].Start < table[j].Start })
+	ccTable = table
+	return nil
+}
+
+// lookupCC returns the country code for ip via binary search over ccTable, or "" if unknown.
+func lookupCC(ip net.IP) string {
+	if len(ccTable) == 0 || !isIPv4(ip.String()) {
+		return ""
+	}
+	num := ipToUint32(ip)
+	i := sort.Search(len(ccTable), func(i int) bool { return ccTable[i].End >= num })
+	if i < len(ccTable) && ccTable[i].Start <= num && num <= ccTable[i].End {
+		return ccTable[i].CC
+	}
+	return ""
+}
+
+// filterByCountry drops any loaded host whose country code isn't in allowed.
+func (r *IPRanges) filterByCountry(allowed []string) {
+	if len(ccTable) == 0 {
+		return
+	}
+	set := make(map[string]bool, len(allowed))
+	for _, cc := range allowed {
+		set[strings.ToUpper(strings.TrimSpace(cc))] = true
+	}
+	filtered := make([]*net.IPAddr, 0, len(r.ips))
+	for _, ip := range r.ips {
+		if set[lookupCC(ip.IP)] {
+			filtered = append(filtered, ip)
+		}
+	}
+	r.ips = filtered
+}
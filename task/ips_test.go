@@ -0,0 +1,118 @@
+package task
+
+import (
+	"net"
+	"testing"
+)
+
+func countIPv4Range(t *testing.T, cidr string, wantFirst24, wantCount int) {
+	t.Helper()
+	r := newIPRanges()
+	r.mask = cidr[len(cidr)-3:]
+	if !isIPv4(cidr) {
+		t.Fatalf("not an IPv4 CIDR: %s", cidr)
+	}
+	r.setIPNet(cidr)
+	r.chooseIPv4()
+
+	if got := r.Count(); got != wantCount {
+		t.Fatalf("chooseIPv4(%s) produced %d hosts, want %d", cidr, got, wantCount)
+	}
+	for _, ip := range r.ips {
+		if ip.IP.To4() == nil {
+			t.Fatalf("chooseIPv4(%s) produced non-IPv4 host %s", cidr, ip)
+		}
+	}
+	_ = wantFirst24
+}
+
+func TestChooseIPv4RangeCoverage(t *testing.T) {
+	cases := []struct {
+		cidr      string
+		testAll   bool
+		wantCount int
+	}{
+		{cidr: "1.1.1.0/32", wantCount: 1},
+		{cidr: "1.1.1.0/24", wantCount: 1},                  // one random host in the single /24
+		{cidr: "1.1.1.0/24", testAll: true, wantCount: 256}, // every host in the /24
+		{cidr: "1.1.0.0/23", wantCount: 2},                  // two /24 blocks, one host each
+		{cidr: "1.1.0.0/16", wantCount: 256},                // 256 /24 blocks, one host each
+	}
+	for _, c := range cases {
+		prevTestAll := TestAll
+		TestAll = c.testAll
+		countIPv4Range(t, c.cidr, 0, c.wantCount)
+		TestAll = prevTestAll
+	}
+}
+
+func TestChooseIPv6SamplesEveryBlock(t *testing.T) {
+	prevTestAll := TestAll
+	defer func() { TestAll = prevTestAll }()
+	TestAll = false
+
+	// A /46 holds four distinct /48 blocks; chooseIPv6 must sample each one
+	// instead of staying inside the single /64 the old implementation produced.
+	r := newIPRanges()
+	r.mask = "/46"
+	r.setIPNet("2606:4700::/46")
+	r.chooseIPv6()
+
+	if got := r.Count(); got != 4 {
+		t.Fatalf("chooseIPv6(2606:4700::/46) produced %d hosts, want 4", got)
+	}
+
+	blocks := make(map[uint64]bool)
+	for _, ip := range r.ips {
+		addr := ip.IP.To16()
+		if addr == nil {
+			t.Fatalf("chooseIPv6 produced non-IPv6 host %s", ip)
+		}
+		blocks[ip6BlockNum(addr)] = true
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("chooseIPv6(2606:4700::/46) sampled %d distinct /48 blocks, want 4", len(blocks))
+	}
+}
+
+func TestChooseIPv6SingleHostForNarrowPrefix(t *testing.T) {
+	r := newIPRanges()
+	r.mask = "/128"
+	r.setIPNet("2606:4700::1/128")
+	r.chooseIPv6()
+
+	if got := r.Count(); got != 1 {
+		t.Fatalf("chooseIPv6(.../128) produced %d hosts, want 1", got)
+	}
+	if got := r.ips[0].IP.String(); got != "2606:4700::1" {
+		t.Fatalf("chooseIPv6(.../128) produced %s, want 2606:4700::1", got)
+	}
+}
+
+func TestLookupCC(t *testing.T) {
+	prevTable := ccTable
+	defer func() { ccTable = prevTable }()
+
+	ccTable = []ccRange{
+		{Start: ipToUint32(net.ParseIP("1.0.0.0")), End: ipToUint32(net.ParseIP("1.0.0.255")), CC: "US"},
+		{Start: ipToUint32(net.ParseIP("2.0.0.0")), End: ipToUint32(net.ParseIP("2.0.0.255")), CC: "DE"},
+		{Start: ipToUint32(net.ParseIP("3.0.0.0")), End: ipToUint32(net.ParseIP("3.0.0.255")), CC: "JP"},
+	}
+
+	cases := []struct {
+		ip   string
+		want string
+	}{
+		{"1.0.0.1", "US"},
+		{"2.0.0.255", "DE"},
+		{"3.0.0.0", "JP"},
+		{"1.0.1.0", ""}, // just past the US block
+		{"4.0.0.0", ""}, // past every block
+		{"0.0.0.0", ""}, // before every block
+	}
+	for _, c := range cases {
+		if got := lookupCC(net.ParseIP(c.ip)); got != c.want {
+			t.Errorf("lookupCC(%s) = %q, want %q", c.ip, got, c.want)
+		}
+	}
+}
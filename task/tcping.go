@@ -0,0 +1,128 @@
+package task
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/mehdi-hexing/Cloudflare-Clean-IP-Scanner/utils"
+)
+
+const (
+	defaultRoutines  = 200
+	maxRoutines      = 1000
+	defaultPort      = 443
+	defaultPingTimes = 4
+)
+
+var (
+	Routines  = defaultRoutines
+	TCPPort   = defaultPort
+	PingTimes = defaultPingTimes
+)
+
+type Ping struct {
+	wg      *sync.WaitGroup
+	m       *sync.Mutex
+	ipRange *IPRanges
+	bar     *pb.ProgressBar
+	control chan bool
+	result  utils.PingDelaySet
+}
+
+func NewPing() *Ping {
+	if Routines <= 0 || Routines > maxRoutines {
+		Routines = defaultRoutines
+	}
+	ranges := loadIPRanges()
+	return &Ping{
+		wg:      &sync.WaitGroup{},
+		m:       &sync.Mutex{},
+		ipRange: ranges,
+		bar:     pb.New(ranges.Count() * PingTimes),
+		control: make(chan bool, Routines),
+		result:  make(utils.PingDelaySet, 0),
+	}
+}
+
+func (p *Ping) Run() utils.PingDelaySet {
+	if p.ipRange.Count() == 0 {
+		return p.result
+	}
+	p.bar.Start()
+	for _, ip := range p.ipRange.ips {
+		p.wg.Add(1)
+		p.control <- true
+		go p.tcpingHandler(ip)
+	}
+	p.wg.Wait()
+	p.bar.Finish()
+	sort.Sort(p.result)
+	return p.result
+}
+
+func (p *Ping) tcpingHandler(ip *net.IPAddr) {
+	defer p.wg.Done()
+	pingData := p.tcping(ip)
+	if pingData.Received > 0 {
+		p.appendResult(pingData)
+	}
+	<-p.control
+}
+
+func (p *Ping) appendResult(data *utils.PingData) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.result = append(p.result, utils.CloudflareIPData{PingData: data})
+}
+
+// addr formats ip:port for net.DialTimeout, bracketing the host whenever the
+// dialed address is actually IPv6 rather than trusting a global toggle.
+func (p *Ping) addr(ip *net.IPAddr) string {
+	if ip.IP.To4() == nil {
+		return fmt.Sprintf("[%s]:%d", ip.String(), TCPPort)
+	}
+	return fmt.Sprintf("%s:%d", ip.String(), TCPPort)
+}
+
+// tcping dials the IP PingTimes times, aborting after two consecutive failures
+// so dead IPs don't waste the full probe budget.
+func (p *Ping) tcping(ip *net.IPAddr) *utils.PingData {
+	data := &utils.PingData{IP: ip}
+	var totalDelay time.Duration
+	consecutiveFails := 0
+
+	for i := 0; i < PingTimes; i++ {
+		data.Sended++
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", p.addr(ip), time.Second)
+		p.bar.Increment()
+		if err != nil {
+			consecutiveFails++
+			if consecutiveFails >= 2 {
+				break
+			}
+			continue
+		}
+		consecutiveFails = 0
+		delay := time.Since(start)
+		conn.Close()
+
+		data.Received++
+		totalDelay += delay
+		if data.MinDelay == 0 || delay < data.MinDelay {
+			data.MinDelay = delay
+		}
+		if delay > data.MaxDelay {
+			data.MaxDelay = delay
+		}
+	}
+
+	if data.Received > 0 {
+		data.Delay = totalDelay / time.Duration(data.Received)
+	}
+	return data
+}
@@ -0,0 +1,54 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Version is set at build time via -ldflags "-X ...task.Version=x.y.z".
+var Version = "dev"
+
+// VersionURL points at the project's raw version file on GitHub.
+var VersionURL = "https://raw.githubusercontent.com/mehdi-hexing/Cloudflare-Clean-IP-Scanner/main/version"
+
+const checkUpdateTimeout = 5 * time.Second
+
+// CheckUpdate compares Version against VersionURL in the background and sends the
+// newer version on the returned channel, which is then closed. Network failures are
+// swallowed so offline users aren't spammed: the channel is closed without a value.
+// Callers read it after the scan finishes and pass the result to PrintUpdateNotice.
+func CheckUpdate() <-chan string {
+	result := make(chan string, 1)
+	go func() {
+		defer close(result)
+		client := &http.Client{Timeout: checkUpdateTimeout}
+		resp, err := client.Get(VersionURL)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return
+		}
+		latest := strings.TrimSpace(string(body))
+		if latest != "" && latest != Version {
+			result <- latest
+		}
+	}()
+	return result
+}
+
+// PrintUpdateNotice prints the new-version banner if newVersion (from CheckUpdate) is non-empty.
+func PrintUpdateNotice(newVersion string) {
+	if newVersion == "" {
+		return
+	}
+	fmt.Printf("\n*** New version [%s] available ***\n", newVersion)
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"net"
+	"time"
+)
+
+var (
+	InputMaxDelay    = maxDelay
+	InputMinDelay    = minDelay
+	InputMaxLossRate = float32(1)
+)
+
+type PingData struct {
+	IP       *net.IPAddr
+	Sended   int
+	Received int
+	MinDelay time.Duration
+	Delay    time.Duration // average delay
+	MaxDelay time.Duration
+}
+
+type CloudflareIPData struct {
+	*PingData
+	LossRate      float32
+	DownloadSpeed float64
+}
+
+// getLossRate returns the recorded loss rate, computing it from Sended/Received if not set.
+func (cf *CloudflareIPData) getLossRate() float32 {
+	if cf.LossRate != 0 {
+		return cf.LossRate
+	}
+	if cf.Sended == 0 {
+		return 1
+	}
+	pingLost := cf.Sended - cf.Received
+	return float32(pingLost) / float32(cf.Sended)
+}
+
+type PingDelaySet []CloudflareIPData
+
+// FilterDelay keeps only IPs whose average delay falls within [InputMinDelay, InputMaxDelay].
+// The set isn't sorted by delay alone (Less sorts by loss rate first), so every
+// entry has to be checked independently rather than relying on a break.
+func (s PingDelaySet) FilterDelay() (data PingDelaySet) {
+	if InputMaxDelay > maxDelay || InputMinDelay < minDelay {
+		return s
+	}
+	for _, v := range s {
+		if v.Delay > InputMaxDelay || v.Delay < InputMinDelay {
+			continue
+		}
+		data = append(data, v)
+	}
+	return
+}
+
+// FilterLossRate keeps only IPs whose loss rate does not exceed InputMaxLossRate.
+func (s PingDelaySet) FilterLossRate() (data PingDelaySet) {
+	if InputMaxLossRate >= 1 {
+		return s
+	}
+	for _, v := range s {
+		if v.getLossRate() > InputMaxLossRate {
+			continue
+		}
+		data = append(data, v)
+	}
+	return
+}
+
+func (s PingDelaySet) Len() int {
+	return len(s)
+}
+func (s PingDelaySet) Less(i, j int) bool {
+	iRate, jRate := s[i].getLossRate(), s[j].getLossRate()
+	if iRate != jRate {
+		return iRate < jRate
+	}
+	return s[i].Delay < s[j].Delay
+}
+func (s PingDelaySet) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+type DownloadSpeedSet []CloudflareIPData
+
+func (s DownloadSpeedSet) Len() int {
+	return len(s)
+}
+func (s DownloadSpeedSet) Less(i, j int) bool {
+	return s[i].DownloadSpeed > s[j].DownloadSpeed
+}
+func (s DownloadSpeedSet) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}